@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+)
+
+// ExecuteCommandContext behaves like ExecuteCommand, but closes the
+// underlying SSH session (aborting the remote command) as soon as ctx is
+// cancelled or past its deadline.
+func (s *server) ExecuteCommandContext(ctx context.Context, command string) ([]byte, error) {
+	if command == "" {
+		return nil, errors.New("no command received")
+	}
+	session, err := s.Client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	type outcome struct {
+		out []byte
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		out, err := session.CombinedOutput(command)
+		done <- outcome{out, err}
+	}()
+
+	select {
+	case res := <-done:
+		return res.out, res.err
+	case <-ctx.Done():
+		session.Close()
+		return nil, ctx.Err()
+	}
+}
+
+// ExecuteCommandsContext behaves like ExecuteCommands, but stops running
+// further commands and returns ctx.Err() as soon as ctx is cancelled.
+func (s *server) ExecuteCommandsContext(ctx context.Context, commands []string) error {
+	if len(commands) == 0 {
+		return errors.New("no commands received")
+	}
+	for _, command := range commands {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := s.ExecuteCommandContext(ctx, command); err != nil {
+			return errors.New("command: " + command + " --- " + err.Error())
+		}
+	}
+	return nil
+}
+
+// UploadFileContext behaves like UploadFile, but aborts the transfer
+// between chunks as soon as ctx is cancelled.
+func (s *server) UploadFileContext(ctx context.Context, srcFilePath, dstPath string) error {
+	opts := defaultTransferOptions()
+	opts.Context = ctx
+	return s.UploadFileWithOptions(srcFilePath, dstPath, opts)
+}
+
+// DownloadFileContext behaves like DownloadFile, but aborts the transfer
+// between chunks as soon as ctx is cancelled.
+func (s *server) DownloadFileContext(ctx context.Context, srcFilePath, dstPath string) error {
+	opts := defaultTransferOptions()
+	opts.Context = ctx
+	return s.DownloadFileWithOptions(srcFilePath, dstPath, opts)
+}
@@ -0,0 +1,36 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExecuteCommandContextRejectsEmptyCommand(t *testing.T) {
+	s := &server{}
+	if _, err := s.ExecuteCommandContext(context.Background(), ""); err == nil {
+		t.Fatal("expected an error for an empty command")
+	}
+}
+
+func TestExecuteCommandsContextRejectsEmptyCommands(t *testing.T) {
+	s := &server{}
+	if err := s.ExecuteCommandsContext(context.Background(), nil); err == nil {
+		t.Fatal("expected an error for an empty command list")
+	}
+}
+
+// TestExecuteCommandsContextStopsOnCancelledContext guards the
+// cancellation plumbing this request added: a context cancelled before (or
+// between) commands must short-circuit the remaining commands rather than
+// running them.
+func TestExecuteCommandsContextStopsOnCancelledContext(t *testing.T) {
+	s := &server{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.ExecuteCommandsContext(ctx, []string{"echo hi", "echo bye"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+}
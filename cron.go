@@ -0,0 +1,150 @@
+package bridge
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// CronEntry is a single line of a user's crontab. Lines that don't
+// parse as "minute hour dom month dow command" — comments and the
+// MAILTO=/PATH=/SHELL=-style environment assignments real crontabs
+// commonly start with — are kept verbatim in Raw instead of being
+// rejected, so they round-trip through AddCronEntry/RemoveCronEntry
+// untouched. Blank lines are dropped.
+type CronEntry struct {
+	Minute  string
+	Hour    string
+	Dom     string
+	Month   string
+	Dow     string
+	Command string
+
+	// Raw, when non-empty, holds a line that isn't a schedule entry;
+	// the other fields are ignored.
+	Raw string
+}
+
+// String formats e back into a crontab line.
+func (e CronEntry) String() string {
+	if e.Raw != "" {
+		return e.Raw
+	}
+	return fmt.Sprintf("%s %s %s %s %s %s", e.Minute, e.Hour, e.Dom, e.Month, e.Dow, e.Command)
+}
+
+var cronLinePattern = regexp.MustCompile(`^(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(\S+)\s+(.+)$`)
+
+// ParseCronEntry parses a single crontab line of the form
+// "minute hour dom month dow command".
+func ParseCronEntry(line string) (CronEntry, error) {
+	groups := cronLinePattern.FindStringSubmatch(strings.TrimSpace(line))
+	if groups == nil {
+		return CronEntry{}, fmt.Errorf("cron: malformed line %q", line)
+	}
+	return CronEntry{
+		Minute:  groups[1],
+		Hour:    groups[2],
+		Dom:     groups[3],
+		Month:   groups[4],
+		Dow:     groups[5],
+		Command: groups[6],
+	}, nil
+}
+
+// noCrontabPattern matches the "no crontab for <user>" message crontab -l
+// and crontab -r print when the user has none, keyed off the server's
+// own username instead of the literal string "no crontab for mobile".
+func (s *server) noCrontabPattern() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)no crontab for ` + regexp.QuoteMeta(s.Username))
+}
+
+// ListCronEntries reads and parses the user's current crontab via
+// `crontab -l`. A user with no crontab yields an empty slice, not an
+// error.
+func (s *server) ListCronEntries() ([]CronEntry, error) {
+	out, err := s.ExecuteCommand("crontab -l")
+	if err != nil {
+		if s.noCrontabPattern().Match(out) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseCrontabText(string(out)), nil
+}
+
+// parseCrontabText parses every line of a crontab, keeping whatever
+// ParseCronEntry rejects (comments, environment assignments) verbatim as
+// CronEntry.Raw rather than failing the whole crontab over one line.
+func parseCrontabText(text string) []CronEntry {
+	var entries []CronEntry
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		entry, err := ParseCronEntry(line)
+		if err != nil || strings.HasPrefix(line, "#") {
+			entry = CronEntry{Raw: line}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// AddCronEntry appends e to the user's crontab.
+func (s *server) AddCronEntry(e CronEntry) error {
+	entries, err := s.ListCronEntries()
+	if err != nil {
+		return err
+	}
+	return s.writeCrontab(append(entries, e))
+}
+
+// RemoveCronEntry removes every entry for which match returns true.
+func (s *server) RemoveCronEntry(match func(CronEntry) bool) error {
+	entries, err := s.ListCronEntries()
+	if err != nil {
+		return err
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if !match(e) {
+			kept = append(kept, e)
+		}
+	}
+	return s.writeCrontab(kept)
+}
+
+// writeCrontab replaces the user's whole crontab by piping entries over
+// stdin to `crontab -`, instead of uploading a file and shelling out to
+// `crontab <path>`.
+func (s *server) writeCrontab(entries []CronEntry) error {
+	session, err := s.Client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := session.Start("crontab -"); err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		if _, err := io.WriteString(stdin, e.String()+"\n"); err != nil {
+			return err
+		}
+	}
+	if err := stdin.Close(); err != nil {
+		return err
+	}
+
+	return session.Wait()
+}
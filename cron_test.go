@@ -0,0 +1,47 @@
+package bridge
+
+import "testing"
+
+func TestParseCronEntry(t *testing.T) {
+	entry, err := ParseCronEntry("*/5 * * * * /usr/bin/true")
+	if err != nil {
+		t.Fatalf("ParseCronEntry: %v", err)
+	}
+	want := CronEntry{Minute: "*/5", Hour: "*", Dom: "*", Month: "*", Dow: "*", Command: "/usr/bin/true"}
+	if entry != want {
+		t.Fatalf("got %+v, want %+v", entry, want)
+	}
+}
+
+func TestParseCronEntryCommandWithSpaces(t *testing.T) {
+	entry, err := ParseCronEntry("0 2 * * 1 /usr/bin/backup --target /data --verbose")
+	if err != nil {
+		t.Fatalf("ParseCronEntry: %v", err)
+	}
+	if want := "/usr/bin/backup --target /data --verbose"; entry.Command != want {
+		t.Fatalf("Command = %q, want %q", entry.Command, want)
+	}
+}
+
+func TestParseCronEntryMalformed(t *testing.T) {
+	if _, err := ParseCronEntry("MAILTO=root"); err == nil {
+		t.Fatal("expected an error for a non-schedule line")
+	}
+}
+
+func TestParseCrontabTextPreservesUnparseableLines(t *testing.T) {
+	text := "MAILTO=root\n\n*/5 * * * * /usr/bin/true\n# a comment\n"
+	entries := parseCrontabText(text)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3: %+v", len(entries), entries)
+	}
+	if entries[0].Raw != "MAILTO=root" {
+		t.Errorf("entries[0].Raw = %q, want %q", entries[0].Raw, "MAILTO=root")
+	}
+	if entries[1].Command != "/usr/bin/true" {
+		t.Errorf("entries[1].Command = %q, want %q", entries[1].Command, "/usr/bin/true")
+	}
+	if entries[2].Raw != "# a comment" {
+		t.Errorf("entries[2].Raw = %q, want %q", entries[2].Raw, "# a comment")
+	}
+}
@@ -0,0 +1,219 @@
+package bridge
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/pkg/sftp"
+)
+
+// File is the minimal handle returned by a Filesystem for a single remote
+// or local entry.
+type File interface {
+	io.ReadWriteCloser
+}
+
+// WalkFunc is called for each entry visited by Filesystem.Walk, mirroring
+// the signature of filepath.WalkFunc without tying Filesystem to the local
+// filesystem's path semantics.
+type WalkFunc func(path string, info os.FileInfo, err error) error
+
+// Filesystem abstracts the file operations bridge needs in order to move
+// data between a local disk, a remote SFTP server, S3, or an in-memory
+// store, so that UploadDir, UploadFileTo, UploadDirTo and DownloadFileFrom
+// can be expressed once as Copy(src, dst Filesystem, ...) instead of
+// per-backend code.
+type Filesystem interface {
+	Open(name string) (File, error)
+	Create(name string) (File, error)
+	Stat(name string) (os.FileInfo, error)
+	Mkdir(name string) error
+	MkdirAll(name string) error
+	Chmod(name string, mode os.FileMode) error
+	ReadDir(name string) ([]os.FileInfo, error)
+	Remove(name string) error
+	Walk(root string, fn WalkFunc) error
+}
+
+// SFTPFilesystem is a Filesystem backed by an established *sftp.Client,
+// i.e. the remote side of an SSH connection. This is the behavior
+// `server` used before the Filesystem abstraction existed.
+type SFTPFilesystem struct {
+	Client *sftp.Client
+}
+
+func (fs *SFTPFilesystem) Open(name string) (File, error) {
+	return fs.Client.Open(name)
+}
+
+func (fs *SFTPFilesystem) Create(name string) (File, error) {
+	return fs.Client.Create(name)
+}
+
+func (fs *SFTPFilesystem) Stat(name string) (os.FileInfo, error) {
+	return fs.Client.Stat(name)
+}
+
+func (fs *SFTPFilesystem) Mkdir(name string) error {
+	return fs.Client.Mkdir(name)
+}
+
+func (fs *SFTPFilesystem) MkdirAll(name string) error {
+	return fs.Client.MkdirAll(name)
+}
+
+func (fs *SFTPFilesystem) Chmod(name string, mode os.FileMode) error {
+	return fs.Client.Chmod(name, mode)
+}
+
+func (fs *SFTPFilesystem) ReadDir(name string) ([]os.FileInfo, error) {
+	return fs.Client.ReadDir(name)
+}
+
+func (fs *SFTPFilesystem) Remove(name string) error {
+	return fs.Client.Remove(name)
+}
+
+func (fs *SFTPFilesystem) Walk(root string, fn WalkFunc) error {
+	walker := fs.Client.Walk(root)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			if err := fn(walker.Path(), nil, err); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fn(walker.Path(), walker.Stat(), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LocalFilesystem is a Filesystem backed by the local disk via the os
+// package, i.e. the client side of an SSH connection.
+type LocalFilesystem struct{}
+
+func (fs *LocalFilesystem) Open(name string) (File, error) {
+	return os.Open(name)
+}
+
+func (fs *LocalFilesystem) Create(name string) (File, error) {
+	return os.Create(name)
+}
+
+func (fs *LocalFilesystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (fs *LocalFilesystem) Mkdir(name string) error {
+	return os.Mkdir(name, os.ModePerm)
+}
+
+func (fs *LocalFilesystem) MkdirAll(name string) error {
+	return os.MkdirAll(name, os.ModePerm)
+}
+
+func (fs *LocalFilesystem) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+func (fs *LocalFilesystem) ReadDir(name string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(name)
+}
+
+func (fs *LocalFilesystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (fs *LocalFilesystem) Walk(root string, fn WalkFunc) error {
+	return filepath.Walk(root, filepath.WalkFunc(fn))
+}
+
+// Copy copies srcPath from src into the directory dstPath on dst, as
+// dstPath/<base name of srcPath>, recursing into directories and
+// preserving the source file mode on each copied file.
+// src and dst may be any combination of Filesystem implementations, so
+// e.g. Copy(local, s3fs, ...) and Copy(sftpA, sftpB, ...) are both valid
+// without any backend-specific code at the call site.
+func Copy(src, dst Filesystem, srcPath, dstPath string) error {
+	info, err := src.Stat(srcPath)
+	if err != nil {
+		return err
+	}
+
+	if info.IsDir() {
+		return copyDir(src, dst, srcPath, dstPath, info)
+	}
+	return copyFile(src, dst, srcPath, dstPath, info)
+}
+
+func copyFile(src, dst Filesystem, srcPath, dstPath string, info os.FileInfo) error {
+	srcFile, err := src.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFilePath := path.Join(dstPath, path.Base(srcPath))
+	dstFile, err := dst.Create(dstFilePath)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	if _, err := io.Copy(dstFile, srcFile); err != nil {
+		return err
+	}
+
+	return dst.Chmod(dstFilePath, info.Mode())
+}
+
+func copyDir(src, dst Filesystem, srcDirPath, dstPath string, info os.FileInfo) error {
+	entries, err := src.ReadDir(srcDirPath)
+	if err != nil {
+		return err
+	}
+
+	dstDirPath := path.Join(dstPath, path.Base(srcDirPath))
+	if err := dst.MkdirAll(dstDirPath); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entrySrcPath := path.Join(srcDirPath, entry.Name())
+		if err := Copy(src, dst, entrySrcPath, dstDirPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var errMemNotExist = errors.New("memfs: no such file or directory")
+
+// UploadFileTo uploads srcFilePath from the local disk to dstPath on an
+// arbitrary Filesystem, so callers can sync local->S3, local->a
+// server's SFTP (via its remoteFS), local->MemFilesystem, etc. It is a
+// free function, not a *server method, since it never needs a live
+// connection of its own.
+func UploadFileTo(dst Filesystem, srcFilePath, dstPath string) error {
+	return Copy(&LocalFilesystem{}, dst, srcFilePath, dstPath)
+}
+
+// UploadDirTo uploads srcDirPath recursively to dstPath on an arbitrary
+// Filesystem.
+func UploadDirTo(dst Filesystem, srcDirPath, dstPath string) error {
+	return Copy(&LocalFilesystem{}, dst, srcDirPath, dstPath)
+}
+
+// DownloadFileFrom downloads srcPath from an arbitrary Filesystem to
+// dstPath on the local disk, so callers can sync S3->local,
+// SFTP->local (via a server's remoteFS), etc.
+func DownloadFileFrom(src Filesystem, srcPath, dstPath string) error {
+	return Copy(src, &LocalFilesystem{}, srcPath, dstPath)
+}
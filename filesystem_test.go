@@ -0,0 +1,66 @@
+package bridge
+
+import (
+	"io/ioutil"
+	"testing"
+)
+
+func mustWriteMemFile(t *testing.T, fs *MemFilesystem, name, contents string) {
+	t.Helper()
+	f, err := fs.Create(name)
+	if err != nil {
+		t.Fatalf("Create(%q): %v", name, err)
+	}
+	if _, err := f.Write([]byte(contents)); err != nil {
+		t.Fatalf("Write(%q): %v", name, err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close(%q): %v", name, err)
+	}
+}
+
+func TestCopyMemFilesystemFile(t *testing.T) {
+	src := NewMemFilesystem()
+	dst := NewMemFilesystem()
+	mustWriteMemFile(t, src, "/greeting.txt", "hello")
+
+	if err := Copy(src, dst, "/greeting.txt", "/"); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	f, err := dst.Open("/greeting.txt")
+	if err != nil {
+		t.Fatalf("Open copied file: %v", err)
+	}
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+	if string(data) != "hello" {
+		t.Fatalf("got %q, want %q", data, "hello")
+	}
+}
+
+func TestCopyMemFilesystemDirRecursesIntoNestedDirs(t *testing.T) {
+	src := NewMemFilesystem()
+	dst := NewMemFilesystem()
+
+	if err := src.Mkdir("/dir"); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	mustWriteMemFile(t, src, "/dir/a.txt", "a")
+	if err := src.Mkdir("/dir/nested"); err != nil {
+		t.Fatalf("Mkdir: %v", err)
+	}
+	mustWriteMemFile(t, src, "/dir/nested/b.txt", "b")
+
+	if err := Copy(src, dst, "/dir", "/"); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+
+	for _, path := range []string{"/dir/a.txt", "/dir/nested/b.txt"} {
+		if _, err := dst.Stat(path); err != nil {
+			t.Errorf("expected %s to exist in dst: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package bridge
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFilesystem is an in-memory Filesystem, useful for exercising Copy
+// without a real disk or SSH connection.
+type MemFilesystem struct {
+	mu      sync.Mutex
+	entries map[string]*memEntry
+}
+
+// NewMemFilesystem returns an empty in-memory Filesystem rooted at "/".
+func NewMemFilesystem() *MemFilesystem {
+	return &MemFilesystem{entries: map[string]*memEntry{
+		"/": {name: "/", dir: true, mode: os.ModeDir | os.ModePerm},
+	}}
+}
+
+type memEntry struct {
+	name    string
+	data    []byte
+	dir     bool
+	mode    os.FileMode
+	modTime time.Time
+}
+
+func (e *memEntry) Name() string       { return path.Base(e.name) }
+func (e *memEntry) Size() int64        { return int64(len(e.data)) }
+func (e *memEntry) Mode() os.FileMode  { return e.mode }
+func (e *memEntry) ModTime() time.Time { return e.modTime }
+func (e *memEntry) IsDir() bool        { return e.dir }
+func (e *memEntry) Sys() interface{}   { return nil }
+
+// memFile is the File handle returned by MemFilesystem.Open/Create; Close
+// commits a write-opened buffer back into the owning filesystem.
+type memFile struct {
+	buf   *bytes.Buffer
+	entry *memEntry
+	write bool
+}
+
+func (f *memFile) Read(p []byte) (int, error)  { return f.buf.Read(p) }
+func (f *memFile) Write(p []byte) (int, error) { return f.buf.Write(p) }
+func (f *memFile) Close() error {
+	if !f.write {
+		return nil
+	}
+	f.entry.data = f.buf.Bytes()
+	f.entry.modTime = time.Now()
+	return nil
+}
+
+func (fs *MemFilesystem) Open(name string) (File, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	entry, ok := fs.entries[name]
+	if !ok {
+		return nil, errMemNotExist
+	}
+	return &memFile{buf: bytes.NewBuffer(append([]byte(nil), entry.data...)), entry: entry}, nil
+}
+
+func (fs *MemFilesystem) Create(name string) (File, error) {
+	fs.mu.Lock()
+	entry := &memEntry{name: name, mode: os.ModePerm, modTime: time.Now()}
+	fs.entries[name] = entry
+	fs.mu.Unlock()
+	return &memFile{buf: &bytes.Buffer{}, entry: entry, write: true}, nil
+}
+
+func (fs *MemFilesystem) Stat(name string) (os.FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	entry, ok := fs.entries[name]
+	if !ok {
+		return nil, errMemNotExist
+	}
+	return entry, nil
+}
+
+func (fs *MemFilesystem) Mkdir(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	fs.entries[name] = &memEntry{name: name, dir: true, mode: os.ModeDir | os.ModePerm, modTime: time.Now()}
+	return nil
+}
+
+func (fs *MemFilesystem) MkdirAll(name string) error {
+	var dir string
+	for _, part := range strings.Split(strings.Trim(name, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		dir = dir + "/" + part
+		if err := fs.Mkdir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fs *MemFilesystem) Chmod(name string, mode os.FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	entry, ok := fs.entries[name]
+	if !ok {
+		return errMemNotExist
+	}
+	entry.mode = mode
+	return nil
+}
+
+func (fs *MemFilesystem) ReadDir(name string) ([]os.FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	prefix := strings.TrimSuffix(name, "/") + "/"
+	var infos []os.FileInfo
+	for p, entry := range fs.entries {
+		if p == name || !strings.HasPrefix(p, prefix) {
+			continue
+		}
+		if strings.Contains(strings.TrimPrefix(p, prefix), "/") {
+			continue
+		}
+		infos = append(infos, entry)
+	}
+	return infos, nil
+}
+
+func (fs *MemFilesystem) Remove(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if _, ok := fs.entries[name]; !ok {
+		return errMemNotExist
+	}
+	delete(fs.entries, name)
+	return nil
+}
+
+func (fs *MemFilesystem) Walk(root string, fn WalkFunc) error {
+	info, err := fs.Stat(root)
+	if err != nil {
+		return fn(root, nil, err)
+	}
+	if err := fn(root, info, nil); err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return nil
+	}
+	entries, err := fs.ReadDir(root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := fs.Walk(path.Join(root, entry.Name()), fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ io.ReadWriteCloser = (*memFile)(nil)
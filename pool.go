@@ -0,0 +1,142 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Result is one server's outcome from a ServerPool fan-out call. Stderr
+// is left empty today since ExecuteCommand reports combined output; it
+// is kept on Result for callers once per-stream capture lands.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	Err      error
+	Duration time.Duration
+}
+
+// ServerPool holds many server connections and runs operations against
+// all of them concurrently, turning bridge from a single-host helper
+// into a small fleet-management primitive.
+type ServerPool struct {
+	servers map[string]*server
+	Workers int
+	Timeout time.Duration
+}
+
+// NewServerPool builds a ServerPool from already-connected servers, keyed
+// by their Address. workers caps how many run concurrently (0 or more
+// than len(servers) means unbounded); perHostTimeout bounds how long a
+// single host's operation may take (0 means no timeout).
+func NewServerPool(servers []*server, workers int, perHostTimeout time.Duration) *ServerPool {
+	byAddress := make(map[string]*server, len(servers))
+	for _, s := range servers {
+		byAddress[s.Address] = s
+	}
+	return &ServerPool{servers: byAddress, Workers: workers, Timeout: perHostTimeout}
+}
+
+// Broadcast runs cmd against every server concurrently and collects each
+// one's output, error, and duration.
+func (p *ServerPool) Broadcast(cmd string) map[string]Result {
+	results := make(map[string]Result, len(p.servers))
+	var mu sync.Mutex
+	p.forEach(context.Background(), func(ctx context.Context, address string, s *server) {
+		start := time.Now()
+		out, err := s.ExecuteCommandContext(ctx, cmd)
+		res := Result{Stdout: string(out), Err: err, Duration: time.Since(start)}
+		mu.Lock()
+		results[address] = res
+		mu.Unlock()
+	})
+	return results
+}
+
+// BroadcastStream behaves like Broadcast but streams each server's
+// Result as soon as it completes, for long-running commands where
+// callers want incremental progress instead of waiting on the slowest
+// host. The channel is closed once every server has reported or ctx is
+// cancelled.
+func (p *ServerPool) BroadcastStream(ctx context.Context, cmd string) <-chan Result {
+	out := make(chan Result)
+	go func() {
+		defer close(out)
+		p.forEach(ctx, func(hostCtx context.Context, address string, s *server) {
+			start := time.Now()
+			stdout, err := s.ExecuteCommandContext(hostCtx, cmd)
+			res := Result{Stdout: string(stdout), Err: err, Duration: time.Since(start)}
+			select {
+			case out <- res:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return out
+}
+
+// UploadFileAll uploads src to dst on every server concurrently.
+func (p *ServerPool) UploadFileAll(src, dst string) map[string]error {
+	return p.runAll(func(ctx context.Context, s *server) error {
+		return s.UploadFileContext(ctx, src, dst)
+	})
+}
+
+// RegisterServiceAll uploads and registers the .service file at path on
+// every server concurrently. RegisterService takes no context, so the
+// pool's per-host timeout does not cut a host's registration short.
+func (p *ServerPool) RegisterServiceAll(path string) map[string]error {
+	return p.runAll(func(ctx context.Context, s *server) error {
+		return s.RegisterService(path)
+	})
+}
+
+func (p *ServerPool) runAll(fn func(ctx context.Context, s *server) error) map[string]error {
+	results := make(map[string]error, len(p.servers))
+	var mu sync.Mutex
+	p.forEach(context.Background(), func(ctx context.Context, address string, s *server) {
+		err := fn(ctx, s)
+		mu.Lock()
+		results[address] = err
+		mu.Unlock()
+	})
+	return results
+}
+
+// forEach runs fn against every server in the pool, bounded by p.Workers
+// concurrent workers and p.Timeout per host. fn is called synchronously
+// within its worker goroutine and given a hostCtx scoped to p.Timeout, so
+// that a host exceeding its timeout cannot keep writing into a result
+// map/channel after the caller has already moved on; fn itself (via e.g.
+// ExecuteCommandContext) is responsible for actually aborting its
+// in-flight work when hostCtx is cancelled.
+func (p *ServerPool) forEach(ctx context.Context, fn func(ctx context.Context, address string, s *server)) {
+	sem := make(chan struct{}, p.workerCount())
+	var wg sync.WaitGroup
+	for address, s := range p.servers {
+		address, s := address, s
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			hostCtx := ctx
+			if p.Timeout > 0 {
+				var cancel context.CancelFunc
+				hostCtx, cancel = context.WithTimeout(ctx, p.Timeout)
+				defer cancel()
+			}
+
+			fn(hostCtx, address, s)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *ServerPool) workerCount() int {
+	if p.Workers <= 0 || p.Workers > len(p.servers) {
+		return len(p.servers)
+	}
+	return p.Workers
+}
@@ -0,0 +1,49 @@
+package bridge
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestForEachWaitsForSlowHostBeforeReturning guards against the race fixed
+// in 6783500: forEach must not return (releasing the caller, e.g. Broadcast,
+// to read/return its results) until every fn goroutine has actually
+// finished, even when a host's hostCtx has already fired.
+func TestForEachWaitsForSlowHostBeforeReturning(t *testing.T) {
+	p := NewServerPool([]*server{{Address: "a"}}, 1, 20*time.Millisecond)
+
+	var finished bool
+	start := time.Now()
+	p.forEach(context.Background(), func(ctx context.Context, address string, s *server) {
+		<-ctx.Done()
+		time.Sleep(30 * time.Millisecond)
+		finished = true
+	})
+
+	if !finished {
+		t.Fatal("forEach returned before its fn finished running")
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Fatalf("forEach returned after %v, too soon to have waited for the slow host", elapsed)
+	}
+}
+
+func TestForEachRunsEveryServer(t *testing.T) {
+	p := NewServerPool([]*server{{Address: "a"}, {Address: "b"}, {Address: "c"}}, 2, 0)
+
+	seen := make(map[string]bool)
+	var mu sync.Mutex
+	p.forEach(context.Background(), func(ctx context.Context, address string, s *server) {
+		mu.Lock()
+		seen[address] = true
+		mu.Unlock()
+	})
+
+	for _, address := range []string{"a", "b", "c"} {
+		if !seen[address] {
+			t.Errorf("forEach never ran for %q", address)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package bridge
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// S3Filesystem is a Filesystem backed by an S3 bucket, primarily intended
+// as a backup/restore target reachable via the same Copy(src, dst, ...)
+// calls used for local and SFTP syncing.
+type S3Filesystem struct {
+	Client *s3.S3
+	Bucket string
+}
+
+// NewS3Filesystem builds an S3Filesystem for bucket using the given AWS
+// session, e.g. session.Must(session.NewSession()).
+func NewS3Filesystem(sess *session.Session, bucket string) *S3Filesystem {
+	return &S3Filesystem{Client: s3.New(sess), Bucket: bucket}
+}
+
+func (fs *S3Filesystem) key(name string) string {
+	return path.Clean(name)
+}
+
+type s3File struct {
+	fs   *S3Filesystem
+	key  string
+	buf  *bytes.Buffer
+	mode os.FileMode
+
+	// write is set for handles returned by Create; only those upload on
+	// Close.
+	write bool
+}
+
+func (f *s3File) Read(p []byte) (int, error) {
+	if f.buf == nil {
+		out, err := f.fs.Client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(f.fs.Bucket),
+			Key:    aws.String(f.key),
+		})
+		if err != nil {
+			return 0, err
+		}
+		defer out.Body.Close()
+		data, err := io.ReadAll(out.Body)
+		if err != nil {
+			return 0, err
+		}
+		f.buf = bytes.NewBuffer(data)
+	}
+	return f.buf.Read(p)
+}
+
+func (f *s3File) Write(p []byte) (int, error) {
+	if f.buf == nil {
+		f.buf = &bytes.Buffer{}
+	}
+	return f.buf.Write(p)
+}
+
+func (f *s3File) Close() error {
+	if !f.write || f.buf == nil {
+		return nil
+	}
+	_, err := f.fs.Client.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(f.fs.Bucket),
+		Key:    aws.String(f.key),
+		Body:   bytes.NewReader(f.buf.Bytes()),
+	})
+	return err
+}
+
+func (fs *S3Filesystem) Open(name string) (File, error) {
+	return &s3File{fs: fs, key: fs.key(name)}, nil
+}
+
+func (fs *S3Filesystem) Create(name string) (File, error) {
+	return &s3File{fs: fs, key: fs.key(name), write: true}, nil
+}
+
+func (fs *S3Filesystem) Stat(name string) (os.FileInfo, error) {
+	key := fs.key(name)
+	head, err := fs.Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(fs.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		// S3 has no real directories: a "directory" key never has an
+		// object at its literal path, only objects nested under
+		// key+"/". Treat it as a directory if anything exists there,
+		// so Copy can recurse into it instead of failing the Stat.
+		if fs.hasObjectsUnder(key) {
+			return &s3FileInfo{name: path.Base(key), dir: true, modTime: time.Now()}, nil
+		}
+		return nil, err
+	}
+	size := int64(0)
+	modTime := time.Now()
+	if head.ContentLength != nil {
+		size = *head.ContentLength
+	}
+	if head.LastModified != nil {
+		modTime = *head.LastModified
+	}
+	return &s3FileInfo{name: path.Base(key), size: size, modTime: modTime}, nil
+}
+
+func (fs *S3Filesystem) hasObjectsUnder(key string) bool {
+	out, err := fs.Client.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket:  aws.String(fs.Bucket),
+		Prefix:  aws.String(key + "/"),
+		MaxKeys: aws.Int64(1),
+	})
+	return err == nil && len(out.Contents) > 0
+}
+
+// Mkdir is a no-op: S3 has no real directories, objects are addressed by
+// their full key.
+func (fs *S3Filesystem) Mkdir(name string) error    { return nil }
+func (fs *S3Filesystem) MkdirAll(name string) error { return nil }
+
+// Chmod is a no-op: S3 object permissions are managed via bucket/object
+// ACLs and IAM policy, not POSIX file modes.
+func (fs *S3Filesystem) Chmod(name string, mode os.FileMode) error { return nil }
+
+func (fs *S3Filesystem) ReadDir(name string) ([]os.FileInfo, error) {
+	prefix := fs.key(name) + "/"
+	out, err := fs.Client.ListObjectsV2(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(fs.Bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []os.FileInfo
+	// CommonPrefixes are the "subdirectories" of a delimited listing;
+	// without these, Copy's recursion can never discover nested
+	// prefixes when S3 is the source.
+	for _, common := range out.CommonPrefixes {
+		dirName := strings.TrimSuffix(strings.TrimPrefix(*common.Prefix, prefix), "/")
+		infos = append(infos, &s3FileInfo{name: dirName, dir: true, modTime: time.Now()})
+	}
+	for _, obj := range out.Contents {
+		if *obj.Key == prefix {
+			// the directory marker object itself, not an entry within it.
+			continue
+		}
+		infos = append(infos, &s3FileInfo{name: path.Base(*obj.Key), size: *obj.Size, modTime: *obj.LastModified})
+	}
+	return infos, nil
+}
+
+func (fs *S3Filesystem) Remove(name string) error {
+	_, err := fs.Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(fs.Bucket),
+		Key:    aws.String(fs.key(name)),
+	})
+	return err
+}
+
+func (fs *S3Filesystem) Walk(root string, fn WalkFunc) error {
+	prefix := fs.key(root)
+	var walkErr error
+	err := fs.Client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(fs.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			info := &s3FileInfo{name: path.Base(*obj.Key), size: *obj.Size, modTime: *obj.LastModified}
+			if err := fn(*obj.Key, info, nil); err != nil {
+				walkErr = err
+				return false
+			}
+		}
+		return true
+	})
+	if walkErr != nil {
+		return walkErr
+	}
+	return err
+}
+
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i *s3FileInfo) Name() string       { return i.name }
+func (i *s3FileInfo) Size() int64        { return i.size }
+func (i *s3FileInfo) Mode() os.FileMode  { return os.ModePerm }
+func (i *s3FileInfo) ModTime() time.Time { return i.modTime }
+func (i *s3FileInfo) IsDir() bool        { return i.dir }
+func (i *s3FileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,185 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// ServerConfig describes how to dial and authenticate a server, replacing
+// the fixed key-only, host-key-ignoring behavior of NewServerWithCert.
+type ServerConfig struct {
+	Address  string
+	Port     int
+	Username string
+
+	// PrivateKey, Password and UseSSHAgent are tried in that order; at
+	// least one must yield a usable auth method.
+	PrivateKey  []byte
+	Password    string
+	UseSSHAgent bool
+
+	// KnownHostsFile is consulted via golang.org/x/crypto/ssh/knownhosts
+	// when HostKeyCallback is not set. One of the two is required.
+	KnownHostsFile  string
+	HostKeyCallback ssh.HostKeyCallback
+
+	// Timeout defaults to the package-level `timeout` (5s) when zero.
+	Timeout time.Duration
+}
+
+// UnknownHostKeyError is returned by NewServer when the remote host's key
+// is not present in KnownHostsFile, carrying the offered key's fingerprint
+// so callers can prompt the user to trust it on first use and append it
+// to their known_hosts file.
+type UnknownHostKeyError struct {
+	Hostname    string
+	Fingerprint string
+	Err         error
+}
+
+func (e *UnknownHostKeyError) Error() string {
+	return fmt.Sprintf("unknown host key for %s (%s): %v", e.Hostname, e.Fingerprint, e.Err)
+}
+
+func (e *UnknownHostKeyError) Unwrap() error {
+	return e.Err
+}
+
+// NewServer creates a server struct using cfg to decide auth methods and
+// host key verification, replacing NewServerWithCert's hard-coded
+// key-only auth and ssh.InsecureIgnoreHostKey.
+func NewServer(cfg ServerConfig) (*server, error) {
+	return NewServerContext(context.Background(), cfg)
+}
+
+// NewServerContext behaves like NewServer, but aborts the dial (and the
+// auth methods it depends on, e.g. dialing the SSH agent socket) as soon
+// as ctx is cancelled.
+func NewServerContext(ctx context.Context, cfg ServerConfig) (*server, error) {
+	authMethods, err := authMethodsFor(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback, err := hostKeyCallbackFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	t := cfg.Timeout
+	if t == 0 {
+		t = timeout * time.Second
+	}
+
+	addr := fmt.Sprintf("%s:%d", cfg.Address, cfg.Port)
+	conn, err := (&net.Dialer{Timeout: t}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            cfg.Username,
+		Auth:            authMethods,
+		Timeout:         t,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+
+	sftpClient, err := sftp.NewClient(client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &server{
+		Address:    cfg.Address,
+		Username:   cfg.Username,
+		Password:   cfg.Password,
+		Client:     client,
+		SFTPClient: sftpClient,
+	}, nil
+}
+
+func authMethodsFor(ctx context.Context, cfg ServerConfig) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+
+	if len(cfg.PrivateKey) > 0 {
+		key, err := ssh.ParsePrivateKey(cfg.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, ssh.PublicKeys(key))
+	}
+
+	if cfg.Password != "" {
+		methods = append(methods, ssh.Password(cfg.Password))
+	}
+
+	if cfg.UseSSHAgent {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "unix", os.Getenv("SSH_AUTH_SOCK"))
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	if len(methods) == 0 {
+		return nil, errors.New("no authentication method configured")
+	}
+
+	return methods, nil
+}
+
+func hostKeyCallbackFor(cfg ServerConfig) (ssh.HostKeyCallback, error) {
+	if cfg.HostKeyCallback != nil {
+		return cfg.HostKeyCallback, nil
+	}
+
+	if cfg.KnownHostsFile == "" {
+		return nil, errors.New("either HostKeyCallback or KnownHostsFile must be set")
+	}
+
+	callback, err := knownhosts.New(cfg.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := callback(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if errors.As(err, &keyErr) && len(keyErr.Want) == 0 {
+			return &UnknownHostKeyError{
+				Hostname:    hostname,
+				Fingerprint: ssh.FingerprintSHA256(key),
+				Err:         err,
+			}
+		}
+		return err
+	}, nil
+}
+
+// NewServerWithCert create a server struct which connects server via id_rsa
+//
+// Deprecated: it ignores the remote host key entirely via
+// ssh.InsecureIgnoreHostKey. Use NewServer with a ServerConfig that sets
+// KnownHostsFile or HostKeyCallback instead.
+func NewServerWithCert(address, user string, port int, cert []byte) (*server, error) {
+	return NewServer(ServerConfig{
+		Address:         address,
+		Port:            port,
+		Username:        user,
+		PrivateKey:      cert,
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+	})
+}
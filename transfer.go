@@ -0,0 +1,329 @@
+package bridge
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+var (
+	errRemoteDirMissing = errors.New("remote directory does not exist")
+	errHashMismatch     = errors.New("transferred file hash does not match source")
+)
+
+// HashAlgorithm selects the digest used to verify a transfer and, for
+// resumable transfers, to decide whether an existing chunk can be
+// skipped.
+type HashAlgorithm int
+
+const (
+	HashMD5 HashAlgorithm = iota
+	HashSHA256
+	HashBLAKE2b
+)
+
+func newHash(algo HashAlgorithm) hash.Hash {
+	switch algo {
+	case HashSHA256:
+		return sha256.New()
+	case HashBLAKE2b:
+		h, _ := blake2b.New256(nil)
+		return h
+	default:
+		return md5.New()
+	}
+}
+
+// ProgressFunc is called as a transfer makes progress, with the number of
+// bytes moved so far and the total size of the file being transferred.
+type ProgressFunc func(bytesDone, bytesTotal int64)
+
+// TransferOptions configures UploadFileWithOptions and
+// DownloadFileWithOptions.
+type TransferOptions struct {
+	// ChunkSize is the size of each concurrently-transferred chunk.
+	// Defaults to 4MB.
+	ChunkSize int64
+	// Concurrency caps how many chunks are in flight at once. Defaults
+	// to 4.
+	Concurrency int
+	// Hash selects the digest used for verification and resume
+	// comparisons. Defaults to HashMD5.
+	Hash HashAlgorithm
+	// Progress, if set, is called after each chunk completes.
+	Progress ProgressFunc
+	// ProgressWriter, if set, receives a "bytesDone/bytesTotal\n" line
+	// after each chunk completes, so any io.Writer-based progress bar (e.g.
+	// mpb) can be wired in without implementing ProgressFunc.
+	ProgressWriter io.Writer
+	// Context, if set, aborts the transfer between chunks once
+	// cancelled or past its deadline. Defaults to context.Background().
+	Context context.Context
+}
+
+func defaultTransferOptions() TransferOptions {
+	return TransferOptions{
+		ChunkSize:   4 << 20,
+		Concurrency: 4,
+	}
+}
+
+func (o TransferOptions) ctx() context.Context {
+	if o.Context == nil {
+		return context.Background()
+	}
+	return o.Context
+}
+
+func (o TransferOptions) chunkSize() int64 {
+	if o.ChunkSize <= 0 {
+		return 4 << 20
+	}
+	return o.ChunkSize
+}
+
+func (o TransferOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return 4
+	}
+	return o.Concurrency
+}
+
+func (o TransferOptions) report(done, total int64) {
+	if o.Progress != nil {
+		o.Progress(done, total)
+	}
+	if o.ProgressWriter != nil {
+		fmt.Fprintf(o.ProgressWriter, "%d/%d\n", done, total)
+	}
+}
+
+type chunkSpan struct {
+	offset int64
+	size   int64
+}
+
+func chunkSpans(total, chunkSize int64) []chunkSpan {
+	if total == 0 {
+		return []chunkSpan{{0, 0}}
+	}
+	spans := make([]chunkSpan, 0, total/chunkSize+1)
+	for offset := int64(0); offset < total; offset += chunkSize {
+		size := chunkSize
+		if offset+size > total {
+			size = total - offset
+		}
+		spans = append(spans, chunkSpan{offset, size})
+	}
+	return spans
+}
+
+// UploadFileWithOptions uploads srcFilePath to dstPath using concurrent
+// chunked writes to the remote SFTP file, resuming from whichever chunks
+// the remote side already has, and reporting progress via opts.
+func (s *server) UploadFileWithOptions(srcFilePath, dstPath string, opts TransferOptions) error {
+	srcInfo, err := os.Stat(srcFilePath)
+	if err != nil {
+		return err
+	}
+	if _, err := s.SFTPClient.Stat(dstPath); err != nil {
+		if os.IsNotExist(err) {
+			return errRemoteDirMissing
+		}
+		return err
+	}
+
+	srcFile, err := os.Open(srcFilePath)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFilePath := path.Join(dstPath, path.Base(srcFilePath))
+	dstFile, err := s.SFTPClient.OpenFile(dstFilePath, os.O_RDWR|os.O_CREATE)
+	if err != nil {
+		return err
+	}
+
+	if err := transferChunks(srcFile, dstFile, srcInfo.Size(), opts); err != nil {
+		dstFile.Close()
+		return err
+	}
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+
+	if err := verifyTransfer(&LocalFilesystem{}, s.remoteFS(), srcFilePath, dstFilePath, opts.Hash); err != nil {
+		return err
+	}
+
+	return s.SFTPClient.Chmod(dstFilePath, srcInfo.Mode())
+}
+
+// DownloadFileWithOptions downloads srcFilePath from the remote SFTP
+// server using concurrent chunked reads, resuming from whichever chunks
+// the local file already has, and reporting progress via opts.
+func (s *server) DownloadFileWithOptions(srcFilePath, dstPath string, opts TransferOptions) error {
+	srcInfo, err := s.SFTPClient.Stat(srcFilePath)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(dstPath); err != nil {
+		if !os.IsNotExist(err) {
+			return err
+		}
+		if err := os.MkdirAll(dstPath, os.ModePerm); err != nil {
+			return err
+		}
+	}
+
+	dstFilePath := path.Join(dstPath, path.Base(srcFilePath))
+	srcFile, err := s.SFTPClient.OpenFile(srcFilePath, os.O_RDONLY)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	dstFile, err := os.OpenFile(dstFilePath, os.O_RDWR|os.O_CREATE, srcInfo.Mode())
+	if err != nil {
+		return err
+	}
+
+	if err := transferChunks(srcFile, dstFile, srcInfo.Size(), opts); err != nil {
+		dstFile.Close()
+		return err
+	}
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+
+	return verifyTransfer(s.remoteFS(), &LocalFilesystem{}, srcFilePath, dstFilePath, opts.Hash)
+}
+
+// transferChunks copies total bytes from src to dst in concurrent
+// chunks, skipping any chunk whose destination content already matches
+// the source (resume), and reporting progress as chunks complete.
+func transferChunks(src, dst interface {
+	io.ReaderAt
+	io.WriterAt
+}, total int64, opts TransferOptions) error {
+	spans := chunkSpans(total, opts.chunkSize())
+
+	var done int64
+	var pending []chunkSpan
+	for _, span := range spans {
+		if span.size > 0 && chunkAlreadyTransferred(src, dst, span, opts.Hash) {
+			done += span.size
+			continue
+		}
+		pending = append(pending, span)
+	}
+	opts.report(done, total)
+
+	ctx := opts.ctx()
+	sem := make(chan struct{}, opts.concurrency())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errCh := make(chan error, len(pending))
+
+	for _, span := range pending {
+		span := span
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			select {
+			case <-ctx.Done():
+				errCh <- ctx.Err()
+				return
+			default:
+			}
+
+			buf := make([]byte, span.size)
+			if _, err := src.ReadAt(buf, span.offset); err != nil && err != io.EOF {
+				errCh <- err
+				return
+			}
+			if _, err := dst.WriteAt(buf, span.offset); err != nil {
+				errCh <- err
+				return
+			}
+
+			mu.Lock()
+			done += span.size
+			opts.report(done, total)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func chunkAlreadyTransferred(src, dst io.ReaderAt, span chunkSpan, algo HashAlgorithm) bool {
+	srcBuf := make([]byte, span.size)
+	if _, err := src.ReadAt(srcBuf, span.offset); err != nil && err != io.EOF {
+		return false
+	}
+	dstBuf := make([]byte, span.size)
+	if _, err := dst.ReadAt(dstBuf, span.offset); err != nil {
+		return false
+	}
+	return hashBytes(srcBuf, algo) == hashBytes(dstBuf, algo)
+}
+
+func hashBytes(b []byte, algo HashAlgorithm) string {
+	h := newHash(algo)
+	h.Write(b)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// verifyTransfer streams srcPath and dstPath through algo and compares
+// digests, replacing the old shelled-out md5sum check (which fails on
+// BSD/macOS remotes whose md5 tool isn't named md5sum).
+func verifyTransfer(srcFS, dstFS Filesystem, srcPath, dstPath string, algo HashAlgorithm) error {
+	srcSum, err := hashFile(srcFS, srcPath, algo)
+	if err != nil {
+		return err
+	}
+	dstSum, err := hashFile(dstFS, dstPath, algo)
+	if err != nil {
+		return err
+	}
+	if srcSum != dstSum {
+		return errHashMismatch
+	}
+	return nil
+}
+
+func hashFile(fs Filesystem, name string, algo HashAlgorithm) (string, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := newHash(algo)
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
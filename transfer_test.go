@@ -0,0 +1,113 @@
+package bridge
+
+import (
+	"io"
+	"sync"
+	"testing"
+)
+
+// memReadWriterAt is a fake io.ReaderAt/io.WriterAt backed by an in-memory
+// buffer, standing in for the *os.File/*sftp.File pair transferChunks is
+// normally called with.
+type memReadWriterAt struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+func newMemReadWriterAt(data []byte) *memReadWriterAt {
+	return &memReadWriterAt{data: append([]byte(nil), data...)}
+}
+
+func (m *memReadWriterAt) ReadAt(p []byte, off int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if int(off) >= len(m.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, m.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (m *memReadWriterAt) WriteAt(p []byte, off int64) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if int(off)+len(p) > len(m.data) {
+		grown := make([]byte, int(off)+len(p))
+		copy(grown, m.data)
+		m.data = grown
+	}
+	return copy(m.data[off:], p), nil
+}
+
+// writeTrackingRW wraps a memReadWriterAt and records the offset of every
+// WriteAt call, so tests can assert which chunks transferChunks actually
+// rewrote.
+type writeTrackingRW struct {
+	*memReadWriterAt
+	mu      sync.Mutex
+	offsets []int64
+}
+
+func (w *writeTrackingRW) WriteAt(p []byte, off int64) (int, error) {
+	w.mu.Lock()
+	w.offsets = append(w.offsets, off)
+	w.mu.Unlock()
+	return w.memReadWriterAt.WriteAt(p, off)
+}
+
+func TestTransferChunksCopiesAllData(t *testing.T) {
+	want := []byte("hello world, this is a chunked transfer test")
+	src := newMemReadWriterAt(want)
+	dst := newMemReadWriterAt(make([]byte, len(want)))
+
+	if err := transferChunks(src, dst, int64(len(want)), TransferOptions{ChunkSize: 8, Concurrency: 3}); err != nil {
+		t.Fatalf("transferChunks: %v", err)
+	}
+	if string(dst.data) != string(want) {
+		t.Fatalf("got %q, want %q", dst.data, want)
+	}
+}
+
+func TestTransferChunksSkipsChunksAlreadyMatching(t *testing.T) {
+	data := []byte("01234567890123456789")
+	src := newMemReadWriterAt(data)
+	dst := &writeTrackingRW{memReadWriterAt: newMemReadWriterAt(data)}
+
+	if err := transferChunks(src, dst, int64(len(data)), TransferOptions{ChunkSize: 5, Concurrency: 2}); err != nil {
+		t.Fatalf("transferChunks: %v", err)
+	}
+	if len(dst.offsets) != 0 {
+		t.Fatalf("expected no writes when dst already matches src (resume-skip), got writes at %v", dst.offsets)
+	}
+}
+
+func TestTransferChunksOnlyWritesChangedChunks(t *testing.T) {
+	src := newMemReadWriterAt([]byte("AAAAABBBBBCCCCC"))
+	dst := &writeTrackingRW{memReadWriterAt: newMemReadWriterAt([]byte("AAAAAxxxxxCCCCC"))}
+
+	if err := transferChunks(src, dst, 15, TransferOptions{ChunkSize: 5, Concurrency: 2}); err != nil {
+		t.Fatalf("transferChunks: %v", err)
+	}
+	if len(dst.offsets) != 1 || dst.offsets[0] != 5 {
+		t.Fatalf("expected exactly one write at offset 5, got %v", dst.offsets)
+	}
+	if string(dst.data) != "AAAAABBBBBCCCCC" {
+		t.Fatalf("got %q", dst.data)
+	}
+}
+
+func TestChunkSpans(t *testing.T) {
+	spans := chunkSpans(25, 10)
+	want := []chunkSpan{{0, 10}, {10, 10}, {20, 5}}
+	if len(spans) != len(want) {
+		t.Fatalf("got %d spans, want %d: %+v", len(spans), len(want), spans)
+	}
+	for i, span := range spans {
+		if span != want[i] {
+			t.Errorf("span %d: got %+v, want %+v", i, span, want[i])
+		}
+	}
+}